@@ -0,0 +1,106 @@
+package savefile
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// countingWriter counts bytes written to it without buffering them,
+// so SaveWithOptions can report ContentLength while streaming.
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+// ErrCorrupted is returned by Verify and Load/LoadLatest when a save's
+// contents no longer hash to the checksum recorded in its metadata
+// sidecar.
+type ErrCorrupted struct {
+	File string
+	Want string
+	Got  string
+}
+
+func (e *ErrCorrupted) Error() string {
+	return fmt.Sprintf("savefile: %s is corrupted: want checksum %s, got %s", e.File, e.Want, e.Got)
+}
+
+// Verify re-hashes file and compares it against the checksum recorded in
+// its metadata sidecar, returning *ErrCorrupted on mismatch. Saves
+// without a sidecar (or without a recorded checksum) cannot be verified
+// and are reported as valid.
+func (s *Saver) Verify(file string) error {
+	meta, err := s.readMetadata(file)
+	if err != nil {
+		return err
+	}
+	if meta == nil || meta.Checksum == "" {
+		return nil
+	}
+
+	f, err := s.storage.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, f); err != nil {
+		return err
+	}
+	if got := hex.EncodeToString(hasher.Sum(nil)); got != meta.Checksum {
+		return &ErrCorrupted{File: file, Want: meta.Checksum, Got: got}
+	}
+	return nil
+}
+
+// LoadLatestValid reads and decodes the most recent save file, like
+// LoadLatest, but on corruption falls back to the next-newest save,
+// and keeps going until one verifies or none remain.
+func (s *Saver) LoadLatestValid(target any) error {
+	files, err := s.storage.List()
+	if err != nil {
+		return err
+	}
+
+	type candidate struct {
+		name string
+		t    time.Time
+	}
+	candidates := make([]candidate, 0, len(files))
+	for _, f := range files {
+		if isMetadataFile(f.Name) || len(f.Name) < 20 {
+			continue
+		}
+		t, err := time.Parse("20060102_150405", f.Name[5:20])
+		if err != nil {
+			continue
+		}
+		candidates = append(candidates, candidate{name: f.Name, t: t})
+	}
+	if len(candidates) == 0 {
+		return errors.New("no valid save files found")
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].t.After(candidates[j].t) })
+
+	for _, c := range candidates {
+		err := s.loadChecked(c.name, target)
+		if err == nil {
+			return nil
+		}
+		var corrupted *ErrCorrupted
+		if !errors.As(err, &corrupted) {
+			return err
+		}
+	}
+	return errors.New("no valid save files found")
+}
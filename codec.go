@@ -0,0 +1,236 @@
+package savefile
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// GzipCodec wraps another EncoderDecoder, compressing its encoded output
+// with gzip and decompressing before handing bytes back to Inner. Chain
+// it to shrink large autosaves, e.g. GzipCodec{Inner: JSONCodec{}}.
+type GzipCodec struct {
+	Inner EncoderDecoder
+}
+
+func (g GzipCodec) Encode(w io.Writer, v any) error {
+	gw := gzip.NewWriter(w)
+	if err := g.Inner.Encode(gw, v); err != nil {
+		gw.Close()
+		return err
+	}
+	return gw.Close()
+}
+
+func (g GzipCodec) Decode(r io.Reader, v any) error {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gr.Close()
+	return g.Inner.Decode(gr, v)
+}
+
+// ZstdCodec wraps another EncoderDecoder with a user-supplied zstd
+// implementation, so this package doesn't need a hard dependency on any
+// particular zstd library. NewWriter/NewReader are typically thin
+// adapters around e.g. klauspost/compress/zstd's NewWriter/NewReader.
+type ZstdCodec struct {
+	Inner     EncoderDecoder
+	NewWriter func(w io.Writer) (io.WriteCloser, error)
+	NewReader func(r io.Reader) (io.ReadCloser, error)
+}
+
+func (z ZstdCodec) Encode(w io.Writer, v any) error {
+	if z.NewWriter == nil {
+		return errors.New("savefile: ZstdCodec.NewWriter is not set")
+	}
+	zw, err := z.NewWriter(w)
+	if err != nil {
+		return err
+	}
+	if err := z.Inner.Encode(zw, v); err != nil {
+		zw.Close()
+		return err
+	}
+	return zw.Close()
+}
+
+func (z ZstdCodec) Decode(r io.Reader, v any) error {
+	if z.NewReader == nil {
+		return errors.New("savefile: ZstdCodec.NewReader is not set")
+	}
+	zr, err := z.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+	return z.Inner.Decode(zr, v)
+}
+
+// AEADCodec wraps another EncoderDecoder with AES-GCM authenticated
+// encryption, e.g. AEADCodec{Inner: JSONCodec{}, Key: key}. Key must be
+// 16, 24 or 32 bytes (AES-128/192/256); derive it from a passphrase with
+// something like scrypt or Argon2 rather than using raw passphrase
+// bytes. Because GCM authenticates the whole message at once, Encode
+// buffers Inner's output rather than streaming it straight through.
+type AEADCodec struct {
+	Inner EncoderDecoder
+	Key   []byte
+}
+
+func (a AEADCodec) gcm() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(a.Key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (a AEADCodec) Encode(w io.Writer, v any) error {
+	gcm, err := a.gcm()
+	if err != nil {
+		return err
+	}
+
+	var plaintext bytes.Buffer
+	if err := a.Inner.Encode(&plaintext, v); err != nil {
+		return err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+	if _, err := w.Write(nonce); err != nil {
+		return err
+	}
+	ciphertext := gcm.Seal(nil, nonce, plaintext.Bytes(), nil)
+	_, err = w.Write(ciphertext)
+	return err
+}
+
+func (a AEADCodec) Decode(r io.Reader, v any) error {
+	gcm, err := a.gcm()
+	if err != nil {
+		return err
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	nonceSize := gcm.NonceSize()
+	if len(data) < nonceSize {
+		return errors.New("savefile: encrypted save is too short to contain a nonce")
+	}
+	nonce, ciphertext := data[:nonceSize], data[nonceSize:]
+
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return err
+	}
+	return a.Inner.Decode(bytes.NewReader(plaintext), v)
+}
+
+// codecExt returns the composed filename extension for a (possibly
+// wrapped) codec, e.g. ".json.gz.enc" for
+// AEADCodec{Inner: GzipCodec{Inner: JSONCodec{}}}.
+func codecExt(c EncoderDecoder) string {
+	switch v := c.(type) {
+	case JSONCodec:
+		return ".json"
+	case GobCodec:
+		return ".bin"
+	case GzipCodec:
+		return codecExt(v.Inner) + ".gz"
+	case ZstdCodec:
+		return codecExt(v.Inner) + ".zst"
+	case AEADCodec:
+		return codecExt(v.Inner) + ".enc"
+	default:
+		return ".dat"
+	}
+}
+
+// findAEADKey searches a codec chain for an AEADCodec and returns its
+// Key, so a save written with one AEADCodec-wrapped Saver can be
+// decoded by codecForSuffix without the caller repeating the key.
+func findAEADKey(c EncoderDecoder) []byte {
+	switch v := c.(type) {
+	case AEADCodec:
+		return v.Key
+	case GzipCodec:
+		return findAEADKey(v.Inner)
+	case ZstdCodec:
+		return findAEADKey(v.Inner)
+	default:
+		return nil
+	}
+}
+
+// findZstdFuncs searches a codec chain for a ZstdCodec and returns its
+// NewWriter/NewReader adapters, for the same reason as findAEADKey.
+func findZstdFuncs(c EncoderDecoder) (func(io.Writer) (io.WriteCloser, error), func(io.Reader) (io.ReadCloser, error)) {
+	switch v := c.(type) {
+	case ZstdCodec:
+		return v.NewWriter, v.NewReader
+	case GzipCodec:
+		return findZstdFuncs(v.Inner)
+	case AEADCodec:
+		return findZstdFuncs(v.Inner)
+	default:
+		return nil, nil
+	}
+}
+
+// codecForSuffix rebuilds the codec chain that produced a save's
+// filename suffix (e.g. ".json.gz.enc"), so a directory can mix saves
+// written with different codec wrappers and still have each one loaded
+// correctly. template is the Saver's configured codec; it supplies
+// parameters that can't be recovered from the filename alone, such as
+// an AEADCodec's Key or a ZstdCodec's NewWriter/NewReader adapters.
+func codecForSuffix(suffix string, template EncoderDecoder) (EncoderDecoder, error) {
+	parts := strings.Split(strings.TrimPrefix(suffix, "."), ".")
+	if len(parts) == 0 || parts[0] == "" {
+		return nil, fmt.Errorf("savefile: save has no codec extension to dispatch on")
+	}
+
+	var codec EncoderDecoder
+	switch parts[0] {
+	case "json":
+		codec = JSONCodec{}
+	case "bin":
+		codec = GobCodec{}
+	default:
+		return nil, fmt.Errorf("savefile: unrecognized base codec extension %q", parts[0])
+	}
+
+	for _, wrap := range parts[1:] {
+		switch wrap {
+		case "gz":
+			codec = GzipCodec{Inner: codec}
+		case "zst":
+			newWriter, newReader := findZstdFuncs(template)
+			if newWriter == nil || newReader == nil {
+				return nil, errors.New("savefile: no ZstdCodec NewWriter/NewReader available to decode a .zst save")
+			}
+			codec = ZstdCodec{Inner: codec, NewWriter: newWriter, NewReader: newReader}
+		case "enc":
+			key := findAEADKey(template)
+			if key == nil {
+				return nil, errors.New("savefile: no AEADCodec key available to decode a .enc save")
+			}
+			codec = AEADCodec{Inner: codec, Key: key}
+		default:
+			return nil, fmt.Errorf("savefile: unrecognized codec extension %q", wrap)
+		}
+	}
+	return codec, nil
+}
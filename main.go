@@ -5,8 +5,6 @@ import (
 	"encoding/json"
 	"errors"
 	"io"
-	"os"
-	"path/filepath"
 	"time"
 )
 
@@ -26,32 +24,40 @@ func (j JSONCodec) Encode(w io.Writer, v any) error { return json.NewEncoder(w).
 func (j JSONCodec) Decode(r io.Reader, v any) error { return json.NewDecoder(r).Decode(v) }
 
 type Saver struct {
-	dir            string
-	maxStoredFiles int
-	codec          EncoderDecoder
+	codec   EncoderDecoder
+	storage Storage
+	policy  *RetentionPolicy
 }
 
 func (s *Saver) fileExt() string {
-	switch s.codec.(type) {
-	case JSONCodec:
-		return ".json"
-	case GobCodec:
-		return ".bin"
-	default:
-		return ".dat"
+	return codecExt(s.codec)
+}
+
+// codecFor returns the codec that can decode saveName, resolved from its
+// filename suffix rather than assumed to be s.codec. This lets a single
+// Saver's directory mix saves written with different (but compatibly
+// wrapped) codecs, e.g. plain .json alongside .json.gz.
+func (s *Saver) codecFor(saveName string) (EncoderDecoder, error) {
+	if len(saveName) < 20 || saveName[20:] == s.fileExt() {
+		return s.codec, nil
 	}
+	return codecForSuffix(saveName[20:], s.codec)
 }
 
-// New creates (or reuses) a save directory at path.
+// New creates (or reuses) a save directory at path, storing files on the
+// local filesystem via PosixStorage.
 func New(path string, codec EncoderDecoder) (*Saver, error) {
-	absPath, err := filepath.Abs(path)
+	storage, err := NewPosixStorage(path)
 	if err != nil {
 		return nil, err
 	}
-	if err := os.MkdirAll(absPath, 0755); err != nil {
-		return nil, err
-	}
-	return &Saver{dir: absPath, codec: codec}, nil
+	return NewWithStorage(storage, codec)
+}
+
+// NewWithStorage creates a Saver backed by an arbitrary Storage, e.g.
+// MemStorage for tests or S3Storage for remote persistence.
+func NewWithStorage(storage Storage, codec EncoderDecoder) (*Saver, error) {
+	return &Saver{storage: storage, codec: codec}, nil
 }
 
 // Creates a saver that has a limit wich is automatically managed after each save using DeleteOld().
@@ -59,44 +65,54 @@ func NewLimit(path string, codec EncoderDecoder, maxFiles int) (*Saver, error) {
 	if maxFiles < 1 {
 		return &Saver{}, errors.New("maxFiles must be atleast 1")
 	}
+	return NewWithPolicy(path, codec, RetentionPolicy{MaxCount: maxFiles})
+}
+
+// NewLimitWithStorage is the Storage-backed equivalent of NewLimit.
+func NewLimitWithStorage(storage Storage, codec EncoderDecoder, maxFiles int) (*Saver, error) {
+	if maxFiles < 1 {
+		return &Saver{}, errors.New("maxFiles must be atleast 1")
+	}
+	return NewWithStoragePolicy(storage, codec, RetentionPolicy{MaxCount: maxFiles})
+}
+
+// NewWithPolicy creates a Saver whose DeleteOld enforces policy instead
+// of the legacy single-oldest-file behavior.
+func NewWithPolicy(path string, codec EncoderDecoder, policy RetentionPolicy) (*Saver, error) {
 	saver, err := New(path, codec)
 	if err != nil {
-		return &Saver{}, err
+		return nil, err
 	}
-	saver.maxStoredFiles = maxFiles
+	saver.policy = &policy
 	return saver, nil
 }
 
-// Save writes data to a new file with a timestamp in its name.
-func (s *Saver) Save(data any) error {
-	if s.maxStoredFiles != 0 {
-		s.DeleteOld()
-	}
-	filename := "save_" + time.Now().Format("20060102_150405") + s.fileExt()
-	path := filepath.Join(s.dir, filename)
-	file, err := os.Create(path)
+// NewWithStoragePolicy is the Storage-backed equivalent of NewWithPolicy.
+func NewWithStoragePolicy(storage Storage, codec EncoderDecoder, policy RetentionPolicy) (*Saver, error) {
+	saver, err := NewWithStorage(storage, codec)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return s.codec.Encode(file, data)
+	saver.policy = &policy
+	return saver, nil
 }
 
-// This function deletes a given file if it exists.
+// This function deletes a given file if it exists, along with its
+// metadata sidecar, if any.
 func (s *Saver) Delete(fileName string) error {
-	fullpath := filepath.Join(s.dir, fileName)
-	_, err := os.Stat(fullpath)
+	_, err := s.storage.Stat(fileName)
 	if err != nil {
 		return err
 	}
-	err = os.Remove(fullpath)
-	if err != nil {
+	if err := s.storage.Remove(fileName); err != nil {
 		return err
 	}
+	s.storage.Remove(metadataName(fileName))
 	return nil
 }
 
 func getOldestFile(s *Saver) (string, int, error) {
-	files, err := os.ReadDir(s.dir)
+	files, err := s.storage.List()
 	if err != nil {
 		return "", 0, err
 	}
@@ -104,13 +120,13 @@ func getOldestFile(s *Saver) (string, int, error) {
 	var oldestFile string
 	saveFilesCount := 0
 	for _, f := range files {
-		if !f.Type().IsRegular() {
+		if isMetadataFile(f.Name) {
 			continue
 		}
-		if len(f.Name()) < 20 { // minimal length check for timestamp pattern
+		if len(f.Name) < 20 { // minimal length check for timestamp pattern
 			continue
 		}
-		timestamp := f.Name()[5:20]
+		timestamp := f.Name[5:20]
 		t, err := time.Parse("20060102_150405", timestamp)
 		if err != nil {
 			continue // skip files that don't match
@@ -118,58 +134,63 @@ func getOldestFile(s *Saver) (string, int, error) {
 		saveFilesCount++
 		if saveFilesCount == 1 || t.Before(oldestTime) {
 			oldestTime = t
-			oldestFile = f.Name()
+			oldestFile = f.Name
 		}
 	}
 	return oldestFile, saveFilesCount, nil
 }
 
-// If the saver is created using NewLimit, this function will delete files until that limit is reached or if the saver is created using New it will delete the oldest file. This function will ignore files that don't follow the save file format.
-func (s *Saver) DeleteOld() error {
-	if s.maxStoredFiles != 0 {
-		for {
-			oldestFile, saveFilesCount, err := getOldestFile(s)
-			if err != nil {
-				return err
-			}
-			if saveFilesCount >= s.maxStoredFiles {
-				if oldestFile != "" {
-					err := os.Remove(filepath.Join(s.dir, oldestFile))
-					if err != nil {
-						return err
-					}
-				}
-			} else {
-				break
+// reapExpired deletes every save whose metadata marks it as expired, and
+// reports how many were removed.
+func (s *Saver) reapExpired() (int, error) {
+	infos, err := s.List()
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	now := time.Now()
+	for _, info := range infos {
+		if info.ExpiresAt != nil && now.After(*info.ExpiresAt) {
+			if err := s.Delete(info.Name); err != nil {
+				return removed, err
 			}
+			removed++
 		}
-	} else {
+	}
+	return removed, nil
+}
+
+// If the saver was created with a RetentionPolicy (via NewLimit or
+// NewWithPolicy), this function deletes files until the policy is
+// satisfied; otherwise it deletes just the oldest file. This function
+// will ignore files that don't follow the save file format. Expired
+// saves are reaped first.
+func (s *Saver) DeleteOld() error {
+	if _, err := s.reapExpired(); err != nil {
+		return err
+	}
+	if s.policy == nil {
 		oldestFile, _, err := getOldestFile(s)
 		if err != nil {
 			return err
 		}
 		if oldestFile != "" {
-			err = os.Remove(oldestFile)
-			if err != nil {
-				return err
-			}
+			return s.Delete(oldestFile)
 		}
+		return nil
 	}
-	return nil
+	return s.applyRetentionPolicy()
 }
 
-// Load reads a fiven file and decodes it.
+// Load reads a fiven file and decodes it, verifying and enforcing any
+// metadata sidecar (checksum, expiry, MaxLoads) along the way.
 func (s *Saver) Load(file string, target any) error {
-	f, err := os.Open(filepath.Join(s.dir, file))
-	if err != nil {
-		return err
-	}
-	return s.codec.Decode(f, target)
+	return s.loadChecked(file, target)
 }
 
 // LoadLatest reads and decodes the most recent save file.
 func (s *Saver) LoadLatest(target any) error {
-	files, err := os.ReadDir(s.dir)
+	files, err := s.storage.List()
 	if err != nil {
 		return err
 	}
@@ -180,29 +201,25 @@ func (s *Saver) LoadLatest(target any) error {
 	var mostRecentFile string
 	var mostRecentTime time.Time
 	for _, f := range files {
-		if !f.Type().IsRegular() {
+		if isMetadataFile(f.Name) {
 			continue
 		}
-		if len(f.Name()) < 20 { // minimal length check for timestamp pattern
+		if len(f.Name) < 20 { // minimal length check for timestamp pattern
 			continue
 		}
-		timestamp := f.Name()[5:20]
+		timestamp := f.Name[5:20]
 		t, err := time.Parse("20060102_150405", timestamp)
 		if err != nil {
 			continue // skip files that don't match
 		}
 		if t.After(mostRecentTime) {
 			mostRecentTime = t
-			mostRecentFile = f.Name()
+			mostRecentFile = f.Name
 		}
 	}
 	if mostRecentFile == "" {
 		return errors.New("no valid save files found")
 	}
 
-	file, err := os.Open(filepath.Join(s.dir, mostRecentFile))
-	if err != nil {
-		return err
-	}
-	return s.codec.Decode(file, target)
+	return s.loadChecked(mostRecentFile, target)
 }
@@ -0,0 +1,268 @@
+package savefile
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"hash"
+	"io"
+	"strings"
+	"time"
+)
+
+const metadataSuffix = ".metadata.json"
+
+// SaveOptions configures the sidecar metadata written alongside a save by
+// SaveWithOptions.
+type SaveOptions struct {
+	// Tags are arbitrary user-supplied labels, queryable via FindByTag.
+	Tags map[string]string
+	// TTL, if non-zero, makes the save expire TTL after it is written.
+	// Expired saves are refused on load and reaped by DeleteOld.
+	TTL time.Duration
+	// MaxLoads, if non-zero, limits how many times the save can be
+	// loaded before it is treated as exhausted and auto-deleted.
+	MaxLoads int
+}
+
+// SaveInfo describes a save file and its sidecar metadata, as returned by
+// List and FindByTag.
+type SaveInfo struct {
+	Name           string
+	CreatedAt      time.Time
+	Codec          string
+	ContentLength  int64
+	Checksum       string
+	Tags           map[string]string
+	ExpiresAt      *time.Time
+	MaxLoads       int
+	LoadsRemaining int
+}
+
+// saveMetadata is the JSON shape persisted in the <name>.metadata.json
+// sidecar. It mirrors SaveInfo minus the derived Name field.
+type saveMetadata struct {
+	CreatedAt      time.Time         `json:"createdAt"`
+	Codec          string            `json:"codec"`
+	ContentLength  int64             `json:"contentLength"`
+	Checksum       string            `json:"checksum"`
+	Tags           map[string]string `json:"tags,omitempty"`
+	ExpiresAt      *time.Time        `json:"expiresAt,omitempty"`
+	MaxLoads       int               `json:"maxLoads,omitempty"`
+	LoadsRemaining int               `json:"loadsRemaining,omitempty"`
+}
+
+func metadataName(saveName string) string {
+	return saveName + metadataSuffix
+}
+
+func isMetadataFile(name string) bool {
+	return strings.HasSuffix(name, metadataSuffix)
+}
+
+// Save writes data to a new file with a timestamp in its name.
+func (s *Saver) Save(data any) error {
+	return s.SaveWithOptions(data, SaveOptions{})
+}
+
+// SaveWithOptions writes data like Save, and additionally writes a
+// <name>.metadata.json sidecar carrying the creation time, codec,
+// content length, SHA-256 checksum, and the tags/TTL/MaxLoads from opts.
+// The encoded payload is streamed straight to storage through a hashing
+// io.MultiWriter rather than being buffered by Saver itself; whether the
+// write as a whole avoids buffering still depends on codec (e.g.
+// AEADCodec must buffer internally to compute its authentication tag).
+func (s *Saver) SaveWithOptions(data any, opts SaveOptions) error {
+	filename := "save_" + time.Now().Format("20060102_150405") + s.fileExt()
+	file, err := s.storage.Create(filename)
+	if err != nil {
+		return err
+	}
+
+	hasher := sha256.New()
+	counter := &countingWriter{}
+	encodeErr := s.codec.Encode(io.MultiWriter(file, hasher, counter), data)
+	closeErr := file.Close()
+	if encodeErr != nil {
+		return encodeErr
+	}
+	if closeErr != nil {
+		return closeErr
+	}
+
+	meta := saveMetadata{
+		CreatedAt:      time.Now(),
+		Codec:          strings.TrimPrefix(s.fileExt(), "."),
+		ContentLength:  counter.n,
+		Checksum:       hex.EncodeToString(hasher.Sum(nil)),
+		Tags:           opts.Tags,
+		MaxLoads:       opts.MaxLoads,
+		LoadsRemaining: opts.MaxLoads,
+	}
+	if opts.TTL > 0 {
+		expires := meta.CreatedAt.Add(opts.TTL)
+		meta.ExpiresAt = &expires
+	}
+	if err := s.writeMetadata(filename, meta); err != nil {
+		return err
+	}
+
+	if s.policy != nil {
+		return s.DeleteOld()
+	}
+	return nil
+}
+
+func (s *Saver) writeMetadata(saveName string, meta saveMetadata) error {
+	file, err := s.storage.Create(metadataName(saveName))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return json.NewEncoder(file).Encode(meta)
+}
+
+// readMetadata returns the sidecar metadata for saveName, or nil if no
+// sidecar exists (e.g. the save predates metadata support).
+func (s *Saver) readMetadata(saveName string) (*saveMetadata, error) {
+	file, err := s.storage.Open(metadataName(saveName))
+	if err != nil {
+		return nil, nil
+	}
+	defer file.Close()
+	var meta saveMetadata
+	if err := json.NewDecoder(file).Decode(&meta); err != nil {
+		return nil, err
+	}
+	return &meta, nil
+}
+
+// ErrExpired is returned by Load/LoadLatest when a save's metadata marks
+// it as past its ExpiresAt.
+var ErrExpired = errors.New("save expired")
+
+// ErrExhausted is returned by Load/LoadLatest when a save's MaxLoads has
+// already been used up.
+var ErrExhausted = errors.New("save load count exhausted")
+
+// loadChecked reads saveName, enforcing any sidecar metadata: it refuses
+// (and deletes) expired or exhausted saves, verifies the checksum, and
+// decrements the remaining load count. The codec used to decode is
+// resolved from saveName's own suffix (see codecFor), not assumed to be
+// s.codec, so a directory can mix saves written with different codecs.
+// The checksum is re-hashed while decoding, via io.TeeReader, rather than
+// buffering the whole payload before decoding it.
+func (s *Saver) loadChecked(saveName string, target any) error {
+	meta, err := s.readMetadata(saveName)
+	if err != nil {
+		return err
+	}
+
+	if meta != nil {
+		if meta.ExpiresAt != nil && time.Now().After(*meta.ExpiresAt) {
+			s.storage.Remove(saveName)
+			s.storage.Remove(metadataName(saveName))
+			return ErrExpired
+		}
+		if meta.MaxLoads > 0 && meta.LoadsRemaining <= 0 {
+			s.storage.Remove(saveName)
+			s.storage.Remove(metadataName(saveName))
+			return ErrExhausted
+		}
+	}
+
+	codec, err := s.codecFor(saveName)
+	if err != nil {
+		return err
+	}
+
+	file, err := s.storage.Open(saveName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var hasher hash.Hash
+	var r io.Reader = file
+	if meta != nil && meta.Checksum != "" {
+		hasher = sha256.New()
+		r = io.TeeReader(file, hasher)
+	}
+
+	decodeErr := codec.Decode(r, target)
+
+	if hasher != nil {
+		// Drain whatever the codec didn't read (it may stop short of
+		// EOF) so the hash covers the whole file, then let a checksum
+		// mismatch take priority over any decode error: a corrupted
+		// payload is the root cause, however the codec happened to fail.
+		if _, err := io.Copy(hasher, file); err != nil {
+			return err
+		}
+		if got := hex.EncodeToString(hasher.Sum(nil)); got != meta.Checksum {
+			return &ErrCorrupted{File: saveName, Want: meta.Checksum, Got: got}
+		}
+	}
+	if decodeErr != nil {
+		return decodeErr
+	}
+
+	if meta != nil && meta.MaxLoads > 0 {
+		meta.LoadsRemaining--
+		if err := s.writeMetadata(saveName, *meta); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// List returns every save in the saver's storage along with its metadata.
+// Saves written before metadata support carry zero-value metadata fields,
+// except CreatedAt and ContentLength, which come from the stored file's
+// modification time and size.
+func (s *Saver) List() ([]SaveInfo, error) {
+	files, err := s.storage.List()
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]SaveInfo, 0, len(files))
+	for _, f := range files {
+		if isMetadataFile(f.Name) {
+			continue
+		}
+		info := SaveInfo{Name: f.Name, CreatedAt: f.ModTime, ContentLength: f.Size}
+		meta, err := s.readMetadata(f.Name)
+		if err != nil {
+			return nil, err
+		}
+		if meta != nil {
+			info.CreatedAt = meta.CreatedAt
+			info.Codec = meta.Codec
+			info.ContentLength = meta.ContentLength
+			info.Checksum = meta.Checksum
+			info.Tags = meta.Tags
+			info.ExpiresAt = meta.ExpiresAt
+			info.MaxLoads = meta.MaxLoads
+			info.LoadsRemaining = meta.LoadsRemaining
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// FindByTag returns every save whose metadata has a tag k with value v.
+// Saves without metadata (or without that tag) are skipped.
+func (s *Saver) FindByTag(k, v string) ([]SaveInfo, error) {
+	all, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	matches := make([]SaveInfo, 0)
+	for _, info := range all {
+		if tv, ok := info.Tags[k]; ok && tv == v {
+			matches = append(matches, info)
+		}
+	}
+	return matches, nil
+}
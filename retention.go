@@ -0,0 +1,146 @@
+package savefile
+
+import (
+	"sort"
+	"time"
+)
+
+// Generational configures grandfather-father-son retention: the newest
+// save in each of the last Hourly hours, Daily days and Weekly weeks that
+// contain a save is kept, everything else is a deletion candidate. Hours,
+// days and weeks are plain buckets counted from time.Time's zero time in
+// UTC (as time.Time.Truncate computes them), so weeks start on Mondays at
+// 00:00 UTC.
+type Generational struct {
+	Hourly int
+	Daily  int
+	Weekly int
+}
+
+// RetentionPolicy replaces the single maxStoredFiles count with several
+// independent limits. DeleteOld applies whichever of these are non-zero;
+// a save must survive all of them to be kept, except the single newest
+// save, which is always kept.
+type RetentionPolicy struct {
+	// MaxCount keeps at most this many saves, newest first.
+	MaxCount int
+	// MaxAge discards saves older than this, regardless of count.
+	MaxAge time.Duration
+	// MaxTotalBytes discards the oldest saves once their combined size
+	// would exceed this many bytes.
+	MaxTotalBytes int64
+	// Generational, if set, first narrows the candidate set down to an
+	// hourly/daily/weekly selection before MaxCount/MaxAge/MaxTotalBytes
+	// are applied to what's left.
+	Generational *Generational
+}
+
+// bucketedNewest returns, from infos (already sorted newest first), the
+// newest entry in each of the first limit distinct buckets of the given
+// size. limit <= 0 selects nothing.
+func bucketedNewest(infos []SaveInfo, bucketSize time.Duration, limit int) []SaveInfo {
+	if limit <= 0 {
+		return nil
+	}
+	seen := make(map[int64]bool, limit)
+	kept := make([]SaveInfo, 0, limit)
+	for _, info := range infos {
+		key := info.CreatedAt.Truncate(bucketSize).Unix()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		kept = append(kept, info)
+		if len(kept) == limit {
+			break
+		}
+	}
+	return kept
+}
+
+// generationalSurvivors narrows infos (sorted newest first) down to the
+// union of its hourly/daily/weekly selections, preserving order.
+func generationalSurvivors(infos []SaveInfo, gen Generational) []SaveInfo {
+	keep := make(map[string]bool)
+	for _, info := range bucketedNewest(infos, time.Hour, gen.Hourly) {
+		keep[info.Name] = true
+	}
+	for _, info := range bucketedNewest(infos, 24*time.Hour, gen.Daily) {
+		keep[info.Name] = true
+	}
+	for _, info := range bucketedNewest(infos, 7*24*time.Hour, gen.Weekly) {
+		keep[info.Name] = true
+	}
+	survivors := make([]SaveInfo, 0, len(keep))
+	for _, info := range infos {
+		if keep[info.Name] {
+			survivors = append(survivors, info)
+		}
+	}
+	return survivors
+}
+
+// applyRetentionPolicy evaluates s.policy against the current saves and
+// deletes whatever doesn't survive it. The single newest save is always
+// kept regardless of policy, so a too-small MaxTotalBytes/MaxAge (or an
+// overly narrow Generational selection) can never reap every save.
+func (s *Saver) applyRetentionPolicy() error {
+	infos, err := s.List()
+	if err != nil {
+		return err
+	}
+	if len(infos) == 0 {
+		return nil
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].CreatedAt.After(infos[j].CreatedAt) })
+	newest := infos[0]
+
+	survivors := infos
+	if s.policy.Generational != nil {
+		survivors = generationalSurvivors(infos, *s.policy.Generational)
+	}
+
+	if s.policy.MaxAge > 0 {
+		cutoff := time.Now().Add(-s.policy.MaxAge)
+		filtered := survivors[:0:0]
+		for _, info := range survivors {
+			if info.CreatedAt.After(cutoff) {
+				filtered = append(filtered, info)
+			}
+		}
+		survivors = filtered
+	}
+
+	if s.policy.MaxCount > 0 && len(survivors) > s.policy.MaxCount {
+		survivors = survivors[:s.policy.MaxCount]
+	}
+
+	if s.policy.MaxTotalBytes > 0 {
+		var total int64
+		cut := len(survivors)
+		for i, info := range survivors {
+			total += info.ContentLength
+			if total > s.policy.MaxTotalBytes {
+				cut = i
+				break
+			}
+		}
+		survivors = survivors[:cut]
+	}
+
+	keep := make(map[string]bool, len(survivors)+1)
+	for _, info := range survivors {
+		keep[info.Name] = true
+	}
+	keep[newest.Name] = true
+
+	for _, info := range infos {
+		if keep[info.Name] {
+			continue
+		}
+		if err := s.Delete(info.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,8 +1,12 @@
 package savefile
 
 import (
+	"errors"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestSaveLoadFile(t *testing.T) {
@@ -33,7 +37,7 @@ func TestSaveLoadFileMultiple(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	files, err := os.ReadDir(saver.dir)
+	files, err := os.ReadDir(saver.storage.(*PosixStorage).Dir)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -60,7 +64,7 @@ func TestManuallyDelete(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	file, err := os.ReadDir(saver.dir)
+	file, err := os.ReadDir(saver.storage.(*PosixStorage).Dir)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -71,6 +75,282 @@ func TestManuallyDelete(t *testing.T) {
 	}
 }
 
+func TestSaveLoadFileMemStorage(t *testing.T) {
+	saver, err := NewLimitWithStorage(NewMemStorage(), JSONCodec{}, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data := [3]string{"mem", "test", "case"}
+	err = saver.Save(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var retrievedData [3]string
+	err = saver.LoadLatest(&retrievedData)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if retrievedData != data {
+		t.Fatalf("the loaded data is not the same as the saved data")
+	}
+}
+
+func TestSaveWithOptionsTagsAndExpiry(t *testing.T) {
+	saver, err := NewWithStorage(NewMemStorage(), JSONCodec{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := saver.SaveWithOptions("keep me", SaveOptions{Tags: map[string]string{"kind": "checkpoint"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	time.Sleep(time.Second) // save filenames have 1s resolution
+	if err := saver.SaveWithOptions("expire me", SaveOptions{TTL: time.Nanosecond}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	time.Sleep(time.Millisecond)
+
+	matches, err := saver.FindByTag("kind", "checkpoint")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(matches) != 1 {
+		t.Fatalf("expected 1 tagged save, got %d", len(matches))
+	}
+
+	var target string
+	err = saver.Load(matches[0].Name, &target)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if target != "keep me" {
+		t.Fatalf("the loaded data is not the same as the saved data")
+	}
+
+	all, err := saver.List()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var expiredName string
+	for _, info := range all {
+		if info.Name != matches[0].Name {
+			expiredName = info.Name
+		}
+	}
+	var expiredTarget string
+	err = saver.Load(expiredName, &expiredTarget)
+	if !errors.Is(err, ErrExpired) {
+		t.Fatalf("expected ErrExpired, got: %v", err)
+	}
+}
+
+func TestLoadLatestValidFallsBackOnCorruption(t *testing.T) {
+	mem := NewMemStorage()
+	saver, err := NewWithStorage(mem, JSONCodec{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := saver.Save("good save"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	time.Sleep(time.Second) // save filenames have 1s resolution
+	if err := saver.Save("latest save, but corrupted later"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	all, err := saver.List()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var latest SaveInfo
+	for _, info := range all {
+		if info.CreatedAt.After(latest.CreatedAt) {
+			latest = info
+		}
+	}
+	mem.files[latest.Name] = []byte("not valid json at all")
+
+	if err := saver.Verify(latest.Name); err == nil {
+		t.Fatalf("expected Verify to report corruption")
+	}
+
+	var target string
+	if err := saver.LoadLatestValid(&target); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if target != "good save" {
+		t.Fatalf("expected fallback to the older, uncorrupted save, got %q", target)
+	}
+}
+
+func TestAtomicSaveIgnoresLeftoverTmpFile(t *testing.T) {
+	saver, err := New("./savefoldertesting/testatomicfolder", JSONCodec{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	posix := saver.storage.(*PosixStorage)
+
+	if err := os.WriteFile(filepath.Join(posix.Dir, "save_20200101_000000.json.tmp"), []byte("partial"), 0644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := saver.Save("real save"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var target string
+	if err := saver.LoadLatest(&target); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if target != "real save" {
+		t.Fatalf("expected LoadLatest to ignore the leftover .tmp file, got %q", target)
+	}
+}
+
+func TestRetentionPolicyMaxCount(t *testing.T) {
+	saver, err := NewWithStoragePolicy(NewMemStorage(), JSONCodec{}, RetentionPolicy{MaxCount: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := saver.Save("save"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		time.Sleep(time.Second) // save filenames have 1s resolution
+	}
+
+	infos, err := saver.List()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(infos) != 2 {
+		t.Fatalf("expected MaxCount to keep 2 saves, got %d", len(infos))
+	}
+}
+
+func TestGzipAndAEADCodecRoundTrip(t *testing.T) {
+	key := make([]byte, 32)
+	codec := AEADCodec{Inner: GzipCodec{Inner: JSONCodec{}}, Key: key}
+
+	saver, err := NewWithStorage(NewMemStorage(), codec)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data := [3]string{"compressed", "and", "encrypted"}
+	if err := saver.Save(data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	infos, err := saver.List()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(infos) != 1 || !strings.HasSuffix(infos[0].Name, ".json.gz.enc") {
+		t.Fatalf("expected a .json.gz.enc save, got %+v", infos)
+	}
+
+	var retrievedData [3]string
+	if err := saver.LoadLatest(&retrievedData); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if retrievedData != data {
+		t.Fatalf("the loaded data is not the same as the saved data")
+	}
+}
+
+func TestRetentionPolicyNeverDeletesNewestSave(t *testing.T) {
+	saver, err := NewWithStoragePolicy(NewMemStorage(), JSONCodec{}, RetentionPolicy{MaxTotalBytes: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := saver.Save("bigger than one byte"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := saver.DeleteOld(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var target string
+	if err := saver.LoadLatest(&target); err != nil {
+		t.Fatalf("expected the newest save to survive a too-small MaxTotalBytes, got: %v", err)
+	}
+	if target != "bigger than one byte" {
+		t.Fatalf("the loaded data is not the same as the saved data")
+	}
+}
+
+func TestLoadDispatchesCodecBySuffix(t *testing.T) {
+	mem := NewMemStorage()
+	// The Saver's own codec is Gzip+JSON, yet it must also load the plain
+	// .json save written by a second Saver, since decoding is picked by
+	// each save's suffix.
+	saver, err := NewWithStorage(mem, GzipCodec{Inner: JSONCodec{}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := saver.Save("gzipped"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	time.Sleep(time.Second) // save filenames have 1s resolution
+
+	plainSaver, err := NewWithStorage(mem, JSONCodec{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := plainSaver.Save("plain"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	infos, err := saver.List()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(infos) != 2 {
+		t.Fatalf("expected 2 saves in the mixed directory, got %d", len(infos))
+	}
+	for _, info := range infos {
+		var target string
+		if err := saver.Load(info.Name, &target); err != nil {
+			t.Fatalf("unexpected error loading %s: %v", info.Name, err)
+		}
+		if target != "gzipped" && target != "plain" {
+			t.Fatalf("unexpected decoded value for %s: %q", info.Name, target)
+		}
+	}
+
+	var latest string
+	if err := saver.LoadLatest(&latest); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if latest != "plain" {
+		t.Fatalf("expected LoadLatest to decode the newer, plain-JSON save, got %q", latest)
+	}
+}
+
+func TestLoadReturnsErrCorruptedForTamperedPayload(t *testing.T) {
+	mem := NewMemStorage()
+	saver, err := NewWithStorage(mem, JSONCodec{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := saver.Save("original"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	infos, err := saver.List()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	mem.files[infos[0].Name] = []byte(`"tampered"`)
+
+	var target string
+	err = saver.Load(infos[0].Name, &target)
+	var corrupted *ErrCorrupted
+	if !errors.As(err, &corrupted) {
+		t.Fatalf("expected *ErrCorrupted, got: %v", err)
+	}
+}
+
 func TestDeleteOldNew(t *testing.T) {
 	saver, err := New("./savefoldertesting/testdeletingfolder", JSONCodec{})
 	if err != nil {
@@ -80,7 +360,7 @@ func TestDeleteOldNew(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	file, err := os.ReadDir(saver.dir)
+	file, err := os.ReadDir(saver.storage.(*PosixStorage).Dir)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
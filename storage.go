@@ -0,0 +1,300 @@
+package savefile
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileInfo is a minimal, backend-agnostic description of a stored file.
+// It carries just enough information for Saver to apply its filename-based
+// timestamp parsing and retention logic without depending on os.FileInfo.
+type FileInfo struct {
+	Name    string
+	Size    int64
+	ModTime time.Time
+}
+
+// Storage abstracts the directory/file operations Saver needs, so a Saver
+// can be backed by the local filesystem, memory, or a remote object store
+// without any change to Save/Load/LoadLatest/DeleteOld.
+type Storage interface {
+	// List returns the entries stored under the saver's directory/prefix.
+	List() ([]FileInfo, error)
+	// Open opens an existing entry for reading.
+	Open(name string) (io.ReadCloser, error)
+	// Create creates (or truncates) an entry for writing.
+	Create(name string) (io.WriteCloser, error)
+	// Remove deletes an entry. It must not return an error if the entry
+	// does not exist.
+	Remove(name string) error
+	// Stat returns info about a single entry.
+	Stat(name string) (FileInfo, error)
+}
+
+// tmpSuffix marks a file as a not-yet-committed write. PosixStorage.Create
+// writes here first and only renames into place once the content is
+// fully written and (by default) fsynced, so a crash mid-write never
+// leaves a partial file under its final name.
+const tmpSuffix = ".tmp"
+
+// PosixStorage stores files in a directory on the local filesystem. It is
+// the default Storage used by New and NewLimit and preserves the behavior
+// Saver had before Storage was introduced.
+type PosixStorage struct {
+	Dir string
+	// SyncOnSave fsyncs each file and the directory entry on Create's
+	// Close, for crash safety. It defaults to true; set it to false to
+	// trade that durability for speed on high-frequency autosaves.
+	SyncOnSave bool
+}
+
+// NewPosixStorage creates (or reuses) a directory at path and returns a
+// PosixStorage rooted there, with SyncOnSave enabled.
+func NewPosixStorage(path string) (*PosixStorage, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(absPath, 0755); err != nil {
+		return nil, err
+	}
+	return &PosixStorage{Dir: absPath, SyncOnSave: true}, nil
+}
+
+func (p *PosixStorage) List() ([]FileInfo, error) {
+	entries, err := os.ReadDir(p.Dir)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]FileInfo, 0, len(entries))
+	for _, e := range entries {
+		if !e.Type().IsRegular() {
+			continue
+		}
+		if strings.HasSuffix(e.Name(), tmpSuffix) {
+			continue
+		}
+		fi, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+		if fi.Size() == 0 {
+			continue
+		}
+		infos = append(infos, FileInfo{Name: fi.Name(), Size: fi.Size(), ModTime: fi.ModTime()})
+	}
+	return infos, nil
+}
+
+func (p *PosixStorage) Open(name string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(p.Dir, name))
+}
+
+// Create writes to a temporary file in Dir and only renames it to name
+// once Close has finished writing (and, if SyncOnSave is set, fsyncing)
+// it, so readers never observe a partially written file under name.
+func (p *PosixStorage) Create(name string) (io.WriteCloser, error) {
+	tmpPath := filepath.Join(p.Dir, name+tmpSuffix)
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return nil, err
+	}
+	return &posixAtomicFile{storage: p, file: file, tmpPath: tmpPath, finalPath: filepath.Join(p.Dir, name)}, nil
+}
+
+// posixAtomicFile buffers writes into a temp file and, on Close, renames
+// it to its final name. When SyncOnSave is set it also syncs the file
+// before the rename and fsyncs the parent directory after it, so the
+// rename itself survives a crash.
+type posixAtomicFile struct {
+	storage   *PosixStorage
+	file      *os.File
+	tmpPath   string
+	finalPath string
+}
+
+func (f *posixAtomicFile) Write(p []byte) (int, error) { return f.file.Write(p) }
+
+func (f *posixAtomicFile) Close() error {
+	if f.storage.SyncOnSave {
+		if err := f.file.Sync(); err != nil {
+			f.file.Close()
+			return err
+		}
+	}
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(f.tmpPath, f.finalPath); err != nil {
+		return err
+	}
+	if !f.storage.SyncOnSave {
+		return nil
+	}
+	dir, err := os.Open(f.storage.Dir)
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	return dir.Sync()
+}
+
+func (p *PosixStorage) Remove(name string) error {
+	err := os.Remove(filepath.Join(p.Dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (p *PosixStorage) Stat(name string) (FileInfo, error) {
+	fi, err := os.Stat(filepath.Join(p.Dir, name))
+	if err != nil {
+		return FileInfo{}, err
+	}
+	return FileInfo{Name: fi.Name(), Size: fi.Size(), ModTime: fi.ModTime()}, nil
+}
+
+// MemStorage is an in-memory Storage, useful for unit tests that exercise
+// Saver without touching disk. The zero value is ready to use.
+type MemStorage struct {
+	mu    sync.Mutex
+	files map[string][]byte
+	times map[string]time.Time
+}
+
+func NewMemStorage() *MemStorage {
+	return &MemStorage{
+		files: make(map[string][]byte),
+		times: make(map[string]time.Time),
+	}
+}
+
+func (m *MemStorage) List() ([]FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	infos := make([]FileInfo, 0, len(m.files))
+	for name, data := range m.files {
+		infos = append(infos, FileInfo{Name: name, Size: int64(len(data)), ModTime: m.times[name]})
+	}
+	return infos, nil
+}
+
+func (m *MemStorage) Open(name string) (io.ReadCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.files[name]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+type memWriteCloser struct {
+	storage *MemStorage
+	name    string
+	buf     bytes.Buffer
+}
+
+func (w *memWriteCloser) Write(p []byte) (int, error) { return w.buf.Write(p) }
+
+func (w *memWriteCloser) Close() error {
+	w.storage.mu.Lock()
+	defer w.storage.mu.Unlock()
+	if w.storage.files == nil {
+		w.storage.files = make(map[string][]byte)
+		w.storage.times = make(map[string]time.Time)
+	}
+	w.storage.files[w.name] = w.buf.Bytes()
+	w.storage.times[w.name] = time.Now()
+	return nil
+}
+
+func (m *MemStorage) Create(name string) (io.WriteCloser, error) {
+	return &memWriteCloser{storage: m, name: name}, nil
+}
+
+func (m *MemStorage) Remove(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.files, name)
+	delete(m.times, name)
+	return nil
+}
+
+func (m *MemStorage) Stat(name string) (FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.files[name]
+	if !ok {
+		return FileInfo{}, os.ErrNotExist
+	}
+	return FileInfo{Name: name, Size: int64(len(data)), ModTime: m.times[name]}, nil
+}
+
+// ObjectClient is the minimal surface Saver needs from an object-store SDK
+// (e.g. the S3 client from aws-sdk-go-v2). Keeping it this small lets
+// S3Storage stay free of a hard dependency on any particular SDK; callers
+// wire up their own client that satisfies it.
+type ObjectClient interface {
+	PutObject(key string, body io.Reader) error
+	GetObject(key string) (io.ReadCloser, error)
+	DeleteObject(key string) error
+	HeadObject(key string) (FileInfo, error)
+	ListObjects(prefix string) ([]FileInfo, error)
+}
+
+// S3Storage adapts an ObjectClient (typically backed by S3 or another
+// S3-compatible object store) to the Storage interface, using Prefix as
+// the save directory's equivalent of a bucket path.
+type S3Storage struct {
+	Client ObjectClient
+	Prefix string
+}
+
+func (s *S3Storage) key(name string) string {
+	if s.Prefix == "" {
+		return name
+	}
+	return s.Prefix + "/" + name
+}
+
+func (s *S3Storage) List() ([]FileInfo, error) {
+	return s.Client.ListObjects(s.Prefix)
+}
+
+func (s *S3Storage) Open(name string) (io.ReadCloser, error) {
+	return s.Client.GetObject(s.key(name))
+}
+
+func (s *S3Storage) Create(name string) (io.WriteCloser, error) {
+	return &s3WriteCloser{client: s.Client, key: s.key(name)}, nil
+}
+
+func (s *S3Storage) Remove(name string) error {
+	return s.Client.DeleteObject(s.key(name))
+}
+
+func (s *S3Storage) Stat(name string) (FileInfo, error) {
+	return s.Client.HeadObject(s.key(name))
+}
+
+// s3WriteCloser buffers a write and performs a single PutObject on Close,
+// since most object-store APIs upload as one request rather than streaming.
+type s3WriteCloser struct {
+	client ObjectClient
+	key    string
+	buf    bytes.Buffer
+}
+
+func (w *s3WriteCloser) Write(p []byte) (int, error) { return w.buf.Write(p) }
+
+func (w *s3WriteCloser) Close() error {
+	return w.client.PutObject(w.key, &w.buf)
+}